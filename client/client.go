@@ -0,0 +1,192 @@
+// Package client provides typed calls over ytfd's Unix socket protocol, so external tools don't
+// have to reverse-engineer the frame encoding themselves.
+package client
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+)
+
+// protocolVersion must match ytfd's own wire format version.
+const protocolVersion byte = 1
+
+type state byte
+
+const (
+	failure state = iota
+	success
+)
+
+// Default socket paths, matching the ones ytfd listens on.
+const (
+	FetchSock    = "/tmp/ytfd.fetch.sock"
+	AddSock      = "/tmp/ytfd.add.sock"
+	GetSock      = "/tmp/ytfd.get.sock"
+	RmSock       = "/tmp/ytfd.rm.sock"
+	SearchSock   = "/tmp/ytfd.search.sock"
+	SubsSock     = "/tmp/ytfd.subs.sock"
+	RefreshSock  = "/tmp/ytfd.refresh.sock"
+	WatchSock    = "/tmp/ytfd.watch.sock"
+	StatusSock   = "/tmp/ytfd.status.sock"
+	DownloadSock = "/tmp/ytfd.download.sock"
+)
+
+// Event is a single new-video notification received from the watch endpoint.
+type Event struct {
+	Channel   string `json:"channel"`
+	Title     string `json:"title"`
+	VideoId   string `json:"videoId"`
+	Published string `json:"published"`
+}
+
+func writeFrame(conn net.Conn, st state, payload []byte) error {
+	header := make([]byte, 6)
+	header[0] = protocolVersion
+	header[1] = byte(st)
+	binary.BigEndian.PutUint32(header[2:], uint32(len(payload)))
+	if _, err := conn.Write(header); err != nil {
+		return fmt.Errorf("failed to write frame header: %v", err)
+	}
+	if len(payload) > 0 {
+		if _, err := conn.Write(payload); err != nil {
+			return fmt.Errorf("failed to write frame payload: %v", err)
+		}
+	}
+	return nil
+}
+
+func readFrame(conn net.Conn) (state, []byte, error) {
+	header := make([]byte, 6)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return failure, nil, fmt.Errorf("failed to read frame header: %v", err)
+	}
+	if header[0] != protocolVersion {
+		return failure, nil, fmt.Errorf("unsupported protocol version %v", header[0])
+	}
+	st := state(header[1])
+	length := binary.BigEndian.Uint32(header[2:])
+	payload := make([]byte, length)
+	if length > 0 {
+		if _, err := io.ReadFull(conn, payload); err != nil {
+			return failure, nil, fmt.Errorf("failed to read frame payload: %v", err)
+		}
+	}
+	return st, payload, nil
+}
+
+// request dials sock, writes payload as a single frame, and returns the response payload as a
+// string. It returns an error both on transport failures and when the daemon reports failure.
+func request(sock string, payload []byte) (string, error) {
+	conn, err := net.Dial("unix", sock)
+	if err != nil {
+		return "", fmt.Errorf("failed to dial '%v': %v", sock, err)
+	}
+	defer conn.Close()
+	if err := writeFrame(conn, success, payload); err != nil {
+		return "", err
+	}
+	st, resp, err := readFrame(conn)
+	if err != nil {
+		return "", err
+	}
+	if st == failure {
+		return "", fmt.Errorf("%v", string(resp))
+	}
+	return string(resp), nil
+}
+
+// Add subscribes to channelName via the daemon's add socket.
+func Add(channelName string) (string, error) {
+	return request(AddSock, []byte(channelName))
+}
+
+// Get retrieves the current feed for channelName via the daemon's get socket.
+func Get(channelName string) (string, error) {
+	return request(GetSock, []byte(channelName))
+}
+
+// Rm unsubscribes from channelName via the daemon's rm socket.
+func Rm(channelName string) (string, error) {
+	return request(RmSock, []byte(channelName))
+}
+
+// Search looks up channel names matching query via the daemon's search socket.
+func Search(query string) (string, error) {
+	return request(SearchSock, []byte(query))
+}
+
+// Subs lists every subscribed channel via the daemon's subs socket.
+func Subs() (string, error) {
+	return request(SubsSock, nil)
+}
+
+// Fetch fetches channelName directly from YouTube without subscribing, via the daemon's fetch
+// socket.
+func Fetch(channelName string) (string, error) {
+	return request(FetchSock, []byte(channelName))
+}
+
+// Refresh pokes the daemon's refresh socket to immediately re-fetch every subscribed channel. The
+// daemon sends no response frame on this socket; it closes the connection once the refresh has
+// run, so Refresh waits for that and always returns an empty string.
+func Refresh() (string, error) {
+	conn, err := net.Dial("unix", RefreshSock)
+	if err != nil {
+		return "", fmt.Errorf("failed to dial '%v': %v", RefreshSock, err)
+	}
+	defer conn.Close()
+	if err := writeFrame(conn, success, nil); err != nil {
+		return "", err
+	}
+	io.Copy(io.Discard, conn) // NOTE: returns once the daemon closes the connection
+	return "", nil
+}
+
+// Status returns the scheduler's lastRun/nextRun/failure count for every subscribed channel via
+// the daemon's status socket.
+func Status() (string, error) {
+	return request(StatusSock, nil)
+}
+
+// Download queues every cached video of channelName (or every subscribed channel, if channelName
+// is "*") for download via the daemon's download socket.
+func Download(channelName string) (string, error) {
+	return request(DownloadSock, []byte(channelName))
+}
+
+// SetAutoDownload persists whether channelName's newly discovered videos should be automatically
+// queued for download, via the daemon's download socket.
+func SetAutoDownload(channelName string, enabled bool) (string, error) {
+	payload := fmt.Sprintf("%v\x00auto=%v", channelName, enabled)
+	return request(DownloadSock, []byte(payload))
+}
+
+// Watch dials the daemon's watch socket, optionally scoping the subscription to a comma-separated
+// list of channel names ("" or "*" means every channel), and calls handle for every event it
+// receives until the connection is closed or handle returns an error.
+func Watch(filter string, handle func(Event) error) error {
+	conn, err := net.Dial("unix", WatchSock)
+	if err != nil {
+		return fmt.Errorf("failed to dial '%v': %v", WatchSock, err)
+	}
+	defer conn.Close()
+	if err := writeFrame(conn, success, []byte(filter)); err != nil {
+		return err
+	}
+	for {
+		_, payload, err := readFrame(conn)
+		if err != nil {
+			return err
+		}
+		var ev Event
+		if err := json.Unmarshal(payload, &ev); err != nil {
+			return fmt.Errorf("failed to decode event: %v", err)
+		}
+		if err := handle(ev); err != nil {
+			return err
+		}
+	}
+}
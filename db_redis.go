@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisDb is a db implementation backed by Redis: a hash per channel holding its metadata, and a
+// sorted set of VideoIds (scored by publish order) pointing at per-video keys, so subscriptions
+// and the last-seen video per channel survive daemon restarts, similar to how other feed daemons
+// keep channel/item state in Redis.
+type redisDb struct {
+	rdb   *redis.Client
+	ctx   context.Context
+	locks *channelLocks
+}
+
+func newRedisDb(addr string) (*redisDb, error) {
+	rdb := redis.NewClient(&redis.Options{Addr: addr})
+	ctx := context.Background()
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis at '%v': %v", addr, err)
+	}
+	return &redisDb{rdb: rdb, ctx: ctx, locks: newChannelLocks()}, nil
+}
+
+func redisChannelKey(c string) string   { return "ytf:channel:" + c }
+func redisVideosKey(c string) string    { return "ytf:channel:" + c + ":videos" }
+func redisDownloadsKey(c string) string { return "ytf:channel:" + c + ":downloads" }
+func redisVideoKey(c string, id string) string {
+	return "ytf:video:" + c + ":" + id
+}
+
+func (rd *redisDb) loadChannel(c string) (*channel, error) {
+	fields, err := rd.rdb.HGetAll(rd.ctx, redisChannelKey(c)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load channel '%v': %v", c, err)
+	}
+	url, ok := fields["url"]
+	if !ok {
+		return nil, fmt.Errorf("not subscribed to channel '%v'", c)
+	}
+	ch := &channel{Name: c, URL: url}
+	if raw, ok := fields["filters"]; ok && raw != "" {
+		var f filters
+		if err := json.Unmarshal([]byte(raw), &f); err == nil {
+			ch.Filters = &f
+		}
+	}
+	ch.AutoDownload = fields["autoDownload"] == "1"
+	if lastRun, ok := fields["lastRun"]; ok && lastRun != "" {
+		sched := &schedule{}
+		if t, err := time.Parse(time.RFC3339, lastRun); err == nil {
+			sched.LastRun = t
+		}
+		if nextRun, ok := fields["nextRun"]; ok {
+			if t, err := time.Parse(time.RFC3339, nextRun); err == nil {
+				sched.NextRun = t
+			}
+		}
+		if failures, ok := fields["failures"]; ok {
+			fmt.Sscanf(failures, "%d", &sched.Failures)
+		}
+		ch.Schedule = sched
+	}
+	ids, err := rd.rdb.ZRange(rd.ctx, redisVideosKey(c), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load videos for channel '%v': %v", c, err)
+	}
+	for _, id := range ids {
+		raw, err := rd.rdb.Get(rd.ctx, redisVideoKey(c, id)).Result()
+		if err != nil {
+			continue
+		}
+		v := &video{}
+		if err := json.Unmarshal([]byte(raw), v); err != nil {
+			continue
+		}
+		ch.Videos = append(ch.Videos, v)
+	}
+	downloadFields, err := rd.rdb.HGetAll(rd.ctx, redisDownloadsKey(c)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load downloads for channel '%v': %v", c, err)
+	}
+	for videoId, raw := range downloadFields {
+		var rec downloadRecord
+		if err := json.Unmarshal([]byte(raw), &rec); err != nil {
+			continue
+		}
+		if ch.Downloads == nil {
+			ch.Downloads = make(map[string]*downloadRecord)
+		}
+		ch.Downloads[videoId] = &rec
+	}
+	return ch, nil
+}
+
+func (rd *redisDb) saveChannel(ch *channel) error {
+	var filtersJSON []byte
+	if ch.Filters != nil {
+		var err error
+		filtersJSON, err = json.Marshal(ch.Filters)
+		if err != nil {
+			return fmt.Errorf("failed to encode filters for channel '%v': %v", ch.Name, err)
+		}
+	}
+	autoDownload := "0"
+	if ch.AutoDownload {
+		autoDownload = "1"
+	}
+	if err := rd.rdb.HSet(rd.ctx, redisChannelKey(ch.Name), "url", ch.URL, "filters", string(filtersJSON), "autoDownload", autoDownload).Err(); err != nil {
+		return fmt.Errorf("failed to save channel '%v': %v", ch.Name, err)
+	}
+	oldIds, err := rd.rdb.ZRange(rd.ctx, redisVideosKey(ch.Name), 0, -1).Result()
+	if err != nil {
+		return fmt.Errorf("failed to list existing videos for channel '%v': %v", ch.Name, err)
+	}
+	if err := rd.rdb.Del(rd.ctx, redisVideosKey(ch.Name)).Err(); err != nil {
+		return fmt.Errorf("failed to clear videos for channel '%v': %v", ch.Name, err)
+	}
+	kept := make(map[string]bool, len(ch.Videos))
+	for i, v := range ch.Videos {
+		kept[v.VideoId] = true
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to encode video '%v': %v", v.VideoId, err)
+		}
+		if err := rd.rdb.Set(rd.ctx, redisVideoKey(ch.Name, v.VideoId), raw, 0).Err(); err != nil {
+			return fmt.Errorf("failed to save video '%v': %v", v.VideoId, err)
+		}
+		// NOTE: lower score == newer, so ZRange comes back in the feed's newest-first order
+		if err := rd.rdb.ZAdd(rd.ctx, redisVideosKey(ch.Name), redis.Z{Score: float64(i), Member: v.VideoId}).Err(); err != nil {
+			return fmt.Errorf("failed to index video '%v': %v", v.VideoId, err)
+		}
+	}
+	// Videos dropped from the feed (rotated out past maxFeedSize) would otherwise leak their
+	// per-video key forever, since nothing else ever references a VideoId once it falls off.
+	var stale []string
+	for _, id := range oldIds {
+		if !kept[id] {
+			stale = append(stale, redisVideoKey(ch.Name, id))
+		}
+	}
+	if len(stale) > 0 {
+		if err := rd.rdb.Del(rd.ctx, stale...).Err(); err != nil {
+			return fmt.Errorf("failed to delete stale videos for channel '%v': %v", ch.Name, err)
+		}
+	}
+	return nil
+}
+
+func (rd *redisDb) add(c string, ch *channel) error {
+	if len(ch.Videos) == 0 {
+		return fmt.Errorf("channel with no videos")
+	}
+	c = normalizeName(c)
+	defer rd.locks.lock(c)()
+	ch.Name = c
+	stored, err := rd.loadChannel(c)
+	if err == nil && len(stored.Videos) > 0 {
+		ch.Filters = stored.Filters
+		ch.AutoDownload = stored.AutoDownload
+		ch.Downloads = stored.Downloads
+		var toDownload []string
+		ch.Videos, toDownload = diffAndNotify(c, stored.Videos, ch.Videos, ch.Filters, ch.AutoDownload)
+		if err := rd.saveChannel(ch); err != nil {
+			return err
+		}
+		queueDownloads(c, toDownload)
+		return nil
+	}
+	ch.Videos = ch.Videos[:min(maxFeedSize, len(ch.Videos))]
+	return rd.saveChannel(ch)
+}
+
+func (rd *redisDb) get(c string) (*channel, error) {
+	return rd.loadChannel(normalizeName(c))
+}
+
+func (rd *redisDb) setFilters(c string, f *filters) error {
+	c = normalizeName(c)
+	ch, err := rd.loadChannel(c)
+	if err != nil {
+		return err
+	}
+	ch.Filters = f
+	return rd.saveChannel(ch)
+}
+
+func (rd *redisDb) setAutoDownload(c string, enabled bool) error {
+	c = normalizeName(c)
+	exists, err := rd.rdb.Exists(rd.ctx, redisChannelKey(c)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to check channel '%v': %v", c, err)
+	}
+	if exists == 0 {
+		return fmt.Errorf("not subscribed to channel '%v'", c)
+	}
+	autoDownload := "0"
+	if enabled {
+		autoDownload = "1"
+	}
+	if err := rd.rdb.HSet(rd.ctx, redisChannelKey(c), "autoDownload", autoDownload).Err(); err != nil {
+		return fmt.Errorf("failed to set auto-download for '%v': %v", c, err)
+	}
+	return nil
+}
+
+func (rd *redisDb) setSchedule(c string, s *schedule) error {
+	c = normalizeName(c)
+	if err := rd.rdb.HSet(rd.ctx, redisChannelKey(c),
+		"lastRun", s.LastRun.Format(time.RFC3339),
+		"nextRun", s.NextRun.Format(time.RFC3339),
+		"failures", s.Failures).Err(); err != nil {
+		return fmt.Errorf("failed to set schedule for '%v': %v", c, err)
+	}
+	return nil
+}
+
+func (rd *redisDb) setDownloadStatus(c string, videoId string, st downloadStatus, reason string) error {
+	c = normalizeName(c)
+	rec := &downloadRecord{VideoId: videoId, Status: st, Reason: reason}
+	raw, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode download record for '%v': %v", videoId, err)
+	}
+	if err := rd.rdb.HSet(rd.ctx, redisDownloadsKey(c), videoId, raw).Err(); err != nil {
+		return fmt.Errorf("failed to set download status for '%v'/'%v': %v", c, videoId, err)
+	}
+	return nil
+}
+
+func (rd *redisDb) rm(c string) error {
+	c = normalizeName(c)
+	ids, err := rd.rdb.ZRange(rd.ctx, redisVideosKey(c), 0, -1).Result()
+	if err != nil {
+		return fmt.Errorf("failed to list videos for channel '%v': %v", c, err)
+	}
+	keys := []string{redisChannelKey(c), redisVideosKey(c), redisDownloadsKey(c)}
+	for _, id := range ids {
+		keys = append(keys, redisVideoKey(c, id))
+	}
+	if err := rd.rdb.Del(rd.ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to remove channel '%v': %v", c, err)
+	}
+	return nil
+}
+
+func (rd *redisDb) subs() ([]*channel, error) {
+	keys, err := rd.rdb.Keys(rd.ctx, "ytf:channel:*").Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list channels: %v", err)
+	}
+	var chs []*channel
+	for _, key := range keys {
+		if strings.HasSuffix(key, ":videos") || strings.HasSuffix(key, ":downloads") {
+			continue
+		}
+		name := strings.TrimPrefix(key, "ytf:channel:")
+		ch, err := rd.loadChannel(name)
+		if err != nil {
+			return nil, err
+		}
+		chs = append(chs, ch)
+	}
+	return chs, nil
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteDb is a db implementation backed by a single-writer SQLite connection (a channels table
+// and a videos table keyed by VideoId), so subscriptions and the last-seen video per channel
+// survive daemon restarts instead of only living in memory.
+type sqliteDb struct {
+	conn  *sql.DB
+	locks *channelLocks
+}
+
+func newSQLiteDb(path string) (*sqliteDb, error) {
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite db '%v': %v", path, err)
+	}
+	conn.SetMaxOpenConns(1) // NOTE: sqlite tolerates only a single writer at a time
+	schema := `
+CREATE TABLE IF NOT EXISTS channels (
+	name          TEXT PRIMARY KEY,
+	url           TEXT NOT NULL,
+	filters       TEXT,
+	auto_download INTEGER NOT NULL DEFAULT 0,
+	last_run      TEXT,
+	next_run      TEXT,
+	failures      INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS videos (
+	channel_name TEXT NOT NULL,
+	video_id     TEXT NOT NULL,
+	title        TEXT NOT NULL,
+	description  TEXT NOT NULL,
+	published    TEXT NOT NULL,
+	link         TEXT NOT NULL,
+	duration     INTEGER NOT NULL,
+	content_type TEXT NOT NULL,
+	position     INTEGER NOT NULL,
+	PRIMARY KEY (channel_name, video_id)
+);
+CREATE TABLE IF NOT EXISTS downloads (
+	channel_name TEXT NOT NULL,
+	video_id     TEXT NOT NULL,
+	status       TEXT NOT NULL,
+	reason       TEXT NOT NULL,
+	PRIMARY KEY (channel_name, video_id)
+);
+`
+	if _, err := conn.Exec(schema); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to initialize sqlite schema: %v", err)
+	}
+	return &sqliteDb{conn: conn, locks: newChannelLocks()}, nil
+}
+
+func (sdb *sqliteDb) loadChannel(c string) (*channel, error) {
+	row := sdb.conn.QueryRow(`SELECT url, filters, auto_download, last_run, next_run, failures FROM channels WHERE name = ?`, c)
+	ch := &channel{Name: c}
+	var filtersJSON sql.NullString
+	var lastRun, nextRun sql.NullString
+	var failures int
+	if err := row.Scan(&ch.URL, &filtersJSON, &ch.AutoDownload, &lastRun, &nextRun, &failures); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("not subscribed to channel '%v'", c)
+		}
+		return nil, fmt.Errorf("failed to load channel '%v': %v", c, err)
+	}
+	if filtersJSON.Valid && filtersJSON.String != "" {
+		var f filters
+		if err := json.Unmarshal([]byte(filtersJSON.String), &f); err == nil {
+			ch.Filters = &f
+		}
+	}
+	if lastRun.Valid && lastRun.String != "" {
+		sched := &schedule{Failures: failures}
+		if t, err := time.Parse(time.RFC3339, lastRun.String); err == nil {
+			sched.LastRun = t
+		}
+		if nextRun.Valid {
+			if t, err := time.Parse(time.RFC3339, nextRun.String); err == nil {
+				sched.NextRun = t
+			}
+		}
+		ch.Schedule = sched
+	}
+	rows, err := sdb.conn.Query(`SELECT video_id, title, description, published, link, duration, content_type FROM videos WHERE channel_name = ? ORDER BY position ASC`, c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load videos for channel '%v': %v", c, err)
+	}
+	for rows.Next() {
+		v := &video{}
+		if err := rows.Scan(&v.VideoId, &v.Title, &v.Description, &v.Published, &v.Link.Href, &v.Duration.Seconds, &v.ContentType); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan video for channel '%v': %v", c, err)
+		}
+		ch.Videos = append(ch.Videos, v)
+	}
+	rows.Close()
+	dlRows, err := sdb.conn.Query(`SELECT video_id, status, reason FROM downloads WHERE channel_name = ?`, c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load downloads for channel '%v': %v", c, err)
+	}
+	defer dlRows.Close()
+	for dlRows.Next() {
+		var rec downloadRecord
+		var status string
+		if err := dlRows.Scan(&rec.VideoId, &status, &rec.Reason); err != nil {
+			return nil, fmt.Errorf("failed to scan download record for channel '%v': %v", c, err)
+		}
+		rec.Status = downloadStatus(status)
+		if ch.Downloads == nil {
+			ch.Downloads = make(map[string]*downloadRecord)
+		}
+		ch.Downloads[rec.VideoId] = &rec
+	}
+	return ch, nil
+}
+
+func (sdb *sqliteDb) saveChannel(ch *channel) error {
+	var filtersJSON []byte
+	if ch.Filters != nil {
+		var err error
+		filtersJSON, err = json.Marshal(ch.Filters)
+		if err != nil {
+			return fmt.Errorf("failed to encode filters for channel '%v': %v", ch.Name, err)
+		}
+	}
+	tx, err := sdb.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	if _, err := tx.Exec(`INSERT INTO channels (name, url, filters, auto_download) VALUES (?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET url = excluded.url, filters = excluded.filters, auto_download = excluded.auto_download`,
+		ch.Name, ch.URL, string(filtersJSON), ch.AutoDownload); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to upsert channel '%v': %v", ch.Name, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM videos WHERE channel_name = ?`, ch.Name); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear videos for channel '%v': %v", ch.Name, err)
+	}
+	for i, v := range ch.Videos {
+		if _, err := tx.Exec(`INSERT INTO videos (channel_name, video_id, title, description, published, link, duration, content_type, position) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			ch.Name, v.VideoId, v.Title, v.Description, v.Published, v.Link.Href, v.Duration.Seconds, v.ContentType, i); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to insert video '%v' for channel '%v': %v", v.VideoId, ch.Name, err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (sdb *sqliteDb) add(c string, ch *channel) error {
+	if len(ch.Videos) == 0 {
+		return fmt.Errorf("channel with no videos")
+	}
+	c = normalizeName(c)
+	defer sdb.locks.lock(c)()
+	ch.Name = c
+	stored, err := sdb.loadChannel(c)
+	if err == nil && len(stored.Videos) > 0 {
+		ch.Filters = stored.Filters
+		ch.AutoDownload = stored.AutoDownload
+		ch.Downloads = stored.Downloads
+		var toDownload []string
+		ch.Videos, toDownload = diffAndNotify(c, stored.Videos, ch.Videos, ch.Filters, ch.AutoDownload)
+		if err := sdb.saveChannel(ch); err != nil {
+			return err
+		}
+		queueDownloads(c, toDownload)
+		return nil
+	}
+	ch.Videos = ch.Videos[:min(maxFeedSize, len(ch.Videos))]
+	return sdb.saveChannel(ch)
+}
+
+func (sdb *sqliteDb) get(c string) (*channel, error) {
+	return sdb.loadChannel(normalizeName(c))
+}
+
+func (sdb *sqliteDb) setFilters(c string, f *filters) error {
+	c = normalizeName(c)
+	ch, err := sdb.loadChannel(c)
+	if err != nil {
+		return err
+	}
+	ch.Filters = f
+	return sdb.saveChannel(ch)
+}
+
+func (sdb *sqliteDb) setAutoDownload(c string, enabled bool) error {
+	c = normalizeName(c)
+	res, err := sdb.conn.Exec(`UPDATE channels SET auto_download = ? WHERE name = ?`, enabled, c)
+	if err != nil {
+		return fmt.Errorf("failed to set auto-download for '%v': %v", c, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("not subscribed to channel '%v'", c)
+	}
+	return nil
+}
+
+func (sdb *sqliteDb) setSchedule(c string, s *schedule) error {
+	c = normalizeName(c)
+	_, err := sdb.conn.Exec(`UPDATE channels SET last_run = ?, next_run = ?, failures = ? WHERE name = ?`,
+		s.LastRun.Format(time.RFC3339), s.NextRun.Format(time.RFC3339), s.Failures, c)
+	if err != nil {
+		return fmt.Errorf("failed to set schedule for '%v': %v", c, err)
+	}
+	return nil
+}
+
+func (sdb *sqliteDb) setDownloadStatus(c string, videoId string, st downloadStatus, reason string) error {
+	c = normalizeName(c)
+	_, err := sdb.conn.Exec(`INSERT INTO downloads (channel_name, video_id, status, reason) VALUES (?, ?, ?, ?)
+		ON CONFLICT(channel_name, video_id) DO UPDATE SET status = excluded.status, reason = excluded.reason`,
+		c, videoId, string(st), reason)
+	if err != nil {
+		return fmt.Errorf("failed to set download status for '%v'/'%v': %v", c, videoId, err)
+	}
+	return nil
+}
+
+func (sdb *sqliteDb) rm(c string) error {
+	c = normalizeName(c)
+	if _, err := sdb.conn.Exec(`DELETE FROM videos WHERE channel_name = ?`, c); err != nil {
+		return fmt.Errorf("failed to remove videos for channel '%v': %v", c, err)
+	}
+	if _, err := sdb.conn.Exec(`DELETE FROM downloads WHERE channel_name = ?`, c); err != nil {
+		return fmt.Errorf("failed to remove downloads for channel '%v': %v", c, err)
+	}
+	if _, err := sdb.conn.Exec(`DELETE FROM channels WHERE name = ?`, c); err != nil {
+		return fmt.Errorf("failed to remove channel '%v': %v", c, err)
+	}
+	return nil
+}
+
+func (sdb *sqliteDb) subs() ([]*channel, error) {
+	rows, err := sdb.conn.Query(`SELECT name FROM channels`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list channels: %v", err)
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan channel name: %v", err)
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	var chs []*channel
+	for _, name := range names {
+		ch, err := sdb.loadChannel(name)
+		if err != nil {
+			return nil, err
+		}
+		chs = append(chs, ch)
+	}
+	return chs, nil
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/kkdai/youtube/v2"
+)
+
+// downloadStatus is the lifecycle state of a single video download.
+type downloadStatus string
+
+const (
+	downloadQueued     downloadStatus = "queued"
+	downloadInProgress downloadStatus = "in-progress"
+	downloadDone       downloadStatus = "done"
+	downloadFailed     downloadStatus = "failed"
+)
+
+// downloadRecord tracks one video's download lifecycle, persisted alongside the channel so
+// restarts don't re-download videos that already finished.
+type downloadRecord struct {
+	VideoId string         `json:"videoId"`
+	Status  downloadStatus `json:"status"`
+	Reason  string         `json:"reason,omitempty"`
+}
+
+// Sink is where downloaded video bytes end up.
+type Sink interface {
+	Put(ctx context.Context, videoID string, r io.Reader, size int64) error
+}
+
+var (
+	flagDownloadDir         *string
+	flagDownloadConcurrency *int
+
+	downloadSink  Sink
+	downloadQueue chan downloadJob
+)
+
+type downloadJob struct {
+	channelName string
+	videoId     string
+}
+
+// progressReader wraps r and periodically logs how many bytes have been read out of total.
+type progressReader struct {
+	r          io.Reader
+	videoId    string
+	total      int64
+	read       int64
+	lastLogged time.Time
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	pr.read += int64(n)
+	if time.Since(pr.lastLogged) > 5*time.Second {
+		fmt.Fprintf(logTo, "[INFO]: download '%v': %v/%v bytes\n", pr.videoId, pr.read, pr.total)
+		pr.lastLogged = time.Now()
+	}
+	return n, err
+}
+
+// newSink picks the download Sink: S3 when bucket is set, otherwise a local directory.
+func newSink(bucket string, dir string) (Sink, error) {
+	if bucket != "" {
+		return newS3Sink(bucket)
+	}
+	return newFileSink(dir)
+}
+
+// startDownloadWorkers launches the bounded worker pool that pulls jobs off downloadQueue and
+// fetches the actual video file via kkdai/youtube into the configured Sink.
+func startDownloadWorkers(concurrency int) {
+	downloadQueue = make(chan downloadJob, 64)
+	for i := 0; i < concurrency; i++ {
+		go downloadWorker()
+	}
+}
+
+func downloadWorker() {
+	client := youtube.Client{}
+	for job := range downloadQueue {
+		if err := feed.setDownloadStatus(job.channelName, job.videoId, downloadInProgress, ""); err != nil {
+			fmt.Fprintf(logTo, "[WARNING]: failed to mark '%v' in-progress: %v\n", job.videoId, err)
+		}
+		if err := runDownload(&client, job); err != nil {
+			fmt.Fprintf(logTo, "[ERROR]: download of '%v' failed: %v\n", job.videoId, err)
+			if serr := feed.setDownloadStatus(job.channelName, job.videoId, downloadFailed, err.Error()); serr != nil {
+				fmt.Fprintf(logTo, "[WARNING]: failed to mark '%v' failed: %v\n", job.videoId, serr)
+			}
+			continue
+		}
+		if err := feed.setDownloadStatus(job.channelName, job.videoId, downloadDone, ""); err != nil {
+			fmt.Fprintf(logTo, "[WARNING]: failed to mark '%v' done: %v\n", job.videoId, err)
+		}
+	}
+}
+
+func runDownload(client *youtube.Client, job downloadJob) error {
+	vid, err := client.GetVideo(job.videoId)
+	if err != nil {
+		return fmt.Errorf("failed to look up video '%v': %v", job.videoId, err)
+	}
+	if len(vid.Formats) == 0 {
+		return fmt.Errorf("video '%v' has no downloadable formats", job.videoId)
+	}
+	format := vid.Formats[0]
+	stream, size, err := client.GetStream(vid, &format)
+	if err != nil {
+		return fmt.Errorf("failed to open stream for '%v': %v", job.videoId, err)
+	}
+	defer stream.Close()
+	pr := &progressReader{r: stream, videoId: job.videoId, total: size, lastLogged: time.Now()}
+	return downloadSink.Put(context.Background(), job.videoId, pr, size)
+}
+
+// queueDownload enqueues videoId for channelName, first recording it as queued in the store so a
+// restart before the worker picks it up still knows about it.
+func queueDownload(channelName string, videoId string) error {
+	if downloadQueue == nil {
+		return fmt.Errorf("download subsystem not started")
+	}
+	if err := feed.setDownloadStatus(channelName, videoId, downloadQueued, ""); err != nil {
+		return err
+	}
+	downloadQueue <- downloadJob{channelName: channelName, videoId: videoId}
+	return nil
+}
+
+func handleDownload(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			fmt.Fprintf(logTo, "[ERROR]: 'download' handler failed to accept connection\n")
+			return
+		}
+		go func(c net.Conn) {
+			defer c.Close()
+			_, payload, err := readFrame(c)
+			if err != nil {
+				fmt.Fprintf(logTo, "[ERROR]: didn't understand request for 'download': %v\n", err)
+				writeFrame(c, failure, []byte(err.Error()))
+				return
+			}
+			// "channel\0auto=true|false" persists the channel's auto-download flag instead of
+			// queuing a one-off download of its cached videos.
+			if parts := bytes.SplitN(payload, []byte{0}, 2); len(parts) == 2 {
+				channelName := string(parts[0])
+				enabled := string(parts[1]) == "auto=true"
+				if err := feed.setAutoDownload(channelName, enabled); err != nil {
+					fmt.Fprintf(logTo, "[ERROR]: failed to set auto-download for '%v': %v\n", channelName, err)
+					writeFrame(c, failure, []byte(err.Error()))
+					return
+				}
+				writeFrame(c, success, []byte(fmt.Sprintf("auto-download set to %v for channel %q", enabled, channelName)))
+				return
+			}
+			channelName := string(payload)
+			var chs []*channel
+			if channelName == "*" {
+				chs, err = feed.subs()
+				if err != nil {
+					writeFrame(c, failure, []byte(err.Error()))
+					return
+				}
+			} else {
+				ch, err := feed.get(channelName)
+				if err != nil {
+					writeFrame(c, failure, []byte(err.Error()))
+					return
+				}
+				chs = []*channel{ch}
+			}
+			queued := 0
+			for _, ch := range chs {
+				for _, v := range ch.Videos {
+					if rec, ok := ch.Downloads[v.VideoId]; ok && (rec.Status == downloadDone || rec.Status == downloadInProgress || rec.Status == downloadQueued) {
+						continue
+					}
+					if err := queueDownload(ch.Name, v.VideoId); err != nil {
+						fmt.Fprintf(logTo, "[WARNING]: failed to queue '%v': %v\n", v.VideoId, err)
+						continue
+					}
+					queued++
+				}
+			}
+			writeFrame(c, success, []byte(fmt.Sprintf("queued %v video(s) for download", queued)))
+		}(conn)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// filters describes the per-channel include/exclude rules applied at notify time and when
+// rendering a channel's videos, so users can subscribe to noisy channels but only hear about the
+// videos they actually care about.
+type filters struct {
+	IncludeRegex       string `json:"includeRegex,omitempty"`
+	ExcludeRegex       string `json:"excludeRegex,omitempty"`
+	ExcludeShorts      bool   `json:"excludeShorts,omitempty"`
+	ExcludeLive        bool   `json:"excludeLive,omitempty"`
+	MinDurationSeconds int    `json:"minDurationSeconds,omitempty"`
+}
+
+func (v *video) isShort() bool {
+	return strings.Contains(v.Link.Href, "/shorts/")
+}
+
+func (v *video) isLive() bool {
+	return strings.Contains(v.ContentType, "x-live")
+}
+
+// matches reports whether v should be surfaced given f. A nil f (no filters set) matches
+// everything.
+func (f *filters) matches(v *video) bool {
+	if f == nil {
+		return true
+	}
+	if f.ExcludeShorts && v.isShort() {
+		return false
+	}
+	if f.ExcludeLive && v.isLive() {
+		return false
+	}
+	if f.MinDurationSeconds > 0 && v.Duration.Seconds < f.MinDurationSeconds {
+		return false
+	}
+	if f.ExcludeRegex != "" {
+		if re, err := regexp.Compile(f.ExcludeRegex); err == nil && re.MatchString(v.Title) {
+			return false
+		}
+	}
+	if f.IncludeRegex != "" {
+		re, err := regexp.Compile(f.IncludeRegex)
+		if err != nil || !re.MatchString(v.Title) {
+			return false
+		}
+	}
+	return true
+}
+
+func handleFilter(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			fmt.Fprintf(logTo, "[ERROR]: 'filter' handler failed to accept connection\n")
+			return
+		}
+		go func(c net.Conn) {
+			defer c.Close()
+			_, buf, err := readFrame(c)
+			if err != nil {
+				fmt.Fprintf(logTo, "[ERROR]: didn't understand input for 'filter': %v\n", err)
+				writeFrame(c, failure, []byte(err.Error()))
+				return
+			}
+			parts := bytes.SplitN(buf, []byte{0}, 2)
+			if len(parts) != 2 {
+				writeFrame(c, failure, []byte("expected 'channel\\0json-filters'"))
+				return
+			}
+			chName := string(parts[0])
+			var f filters
+			if err := json.Unmarshal(parts[1], &f); err != nil {
+				fmt.Fprintf(logTo, "[ERROR]: failed to parse filters for '%v': %v\n", chName, err)
+				writeFrame(c, failure, []byte(err.Error()))
+				return
+			}
+			if err := feed.setFilters(chName, &f); err != nil {
+				fmt.Fprintf(logTo, "[ERROR]: failed to set filters for '%v': %v\n", chName, err)
+				writeFrame(c, failure, []byte(err.Error()))
+				return
+			}
+			writeFrame(c, success, []byte(fmt.Sprintf("filters updated for channel %q", chName)))
+		}(conn)
+	}
+}
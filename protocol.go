@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+)
+
+// protocolVersion is the wire format version written in the first byte of every frame, so future
+// changes (e.g. gob or JSON payloads for the streaming watch endpoint) can coexist with it.
+const protocolVersion byte = 1
+
+// writeFrame writes a single versioned, length-prefixed frame to conn:
+//   - 1 byte protocol version
+//   - 1 byte state/opcode
+//   - 4 bytes big-endian payload length
+//   - payload
+func writeFrame(conn net.Conn, st state, payload []byte) error {
+	header := make([]byte, 6)
+	header[0] = protocolVersion
+	header[1] = byte(st)
+	binary.BigEndian.PutUint32(header[2:], uint32(len(payload)))
+	if _, err := conn.Write(header); err != nil {
+		return fmt.Errorf("failed to write frame header: %v", err)
+	}
+	if len(payload) > 0 {
+		if _, err := conn.Write(payload); err != nil {
+			return fmt.Errorf("failed to write frame payload: %v", err)
+		}
+	}
+	return nil
+}
+
+// readFrame reads a single versioned, length-prefixed frame from conn, looping until the full
+// header and payload are read so long requests (e.g. long handleSearch queries) are never
+// silently truncated the way the old fixed 128-byte reads were.
+func readFrame(conn net.Conn) (state, []byte, error) {
+	header := make([]byte, 6)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return failure, nil, fmt.Errorf("failed to read frame header: %v", err)
+	}
+	if header[0] != protocolVersion {
+		return failure, nil, fmt.Errorf("unsupported protocol version %v", header[0])
+	}
+	st := state(header[1])
+	length := binary.BigEndian.Uint32(header[2:])
+	payload := make([]byte, length)
+	if length > 0 {
+		if _, err := io.ReadFull(conn, payload); err != nil {
+			return failure, nil, fmt.Errorf("failed to read frame payload: %v", err)
+		}
+	}
+	return st, payload, nil
+}
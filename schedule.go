@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// schedule tracks when a channel was last refreshed, when it's due next, and how many times in a
+// row its refresh has failed, so the scheduler can debug-and-backoff per channel instead of
+// treating every subscription the same.
+type schedule struct {
+	LastRun  time.Time
+	NextRun  time.Time
+	Failures int
+}
+
+// minRate and maxRate bound how often any single channel is refreshed; set from -minrefrate and
+// -refrate in main() before runScheduler starts.
+var (
+	minRate time.Duration
+	maxRate time.Duration
+)
+
+// avgUploadInterval estimates how often a channel uploads from the gaps between its cached
+// videos' Published timestamps (videos are stored newest-first). Returns false when there aren't
+// at least two parseable timestamps to compare.
+func avgUploadInterval(vids []*video) (time.Duration, bool) {
+	var times []time.Time
+	for _, v := range vids {
+		t, err := time.Parse(time.RFC3339, v.Published)
+		if err != nil {
+			continue
+		}
+		times = append(times, t)
+	}
+	if len(times) < 2 {
+		return 0, false
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].After(times[j]) })
+	var total time.Duration
+	for i := 0; i < len(times)-1; i++ {
+		total += times[i].Sub(times[i+1])
+	}
+	return total / time.Duration(len(times)-1), true
+}
+
+func clampDuration(d time.Duration, lo time.Duration, hi time.Duration) time.Duration {
+	return min(max(d, lo), hi)
+}
+
+// jitter scales d by a random factor in [0.8, 1.2], spreading refreshes of channels that would
+// otherwise land on the same schedule so they don't all hit YouTube in the same tick.
+func jitter(d time.Duration) time.Duration {
+	factor := 0.8 + rand.Float64()*0.4
+	return time.Duration(float64(d) * factor)
+}
+
+// nextInterval picks how long to wait before refreshing ch again: a quarter of its estimated
+// upload interval (falling back to maxRate when there's not enough history), clamped to
+// [minRate, maxRate], backed off exponentially (2x, 4x, 8x, ... capped at maxRate) for every
+// consecutive failure, then jittered by ±20%.
+func nextInterval(ch *channel, failures int) time.Duration {
+	base := maxRate
+	if avg, ok := avgUploadInterval(ch.Videos); ok {
+		base = avg / 4
+	}
+	base = clampDuration(base, minRate, maxRate)
+	if failures > 0 {
+		base = clampDuration(base*time.Duration(1<<min(failures, 10)), minRate, maxRate)
+	}
+	return jitter(base)
+}
+
+// refreshChannel fetches ch, persists the result and updates its schedule (lastRun/nextRun and
+// failure count) through the db interface so the schedule survives restarts like everything else.
+func refreshChannel(ch *channel) {
+	sched := ch.Schedule
+	if sched == nil {
+		sched = &schedule{}
+	}
+	now := time.Now()
+	fetchedChannel, err := fetch([]byte(ch.Name), []byte(ch.URL))
+	if err != nil {
+		fmt.Fprintf(logTo, "[ERROR]: failed to fetch '%v' for refresh: %v\n", ch.Name, err)
+		sched.Failures++
+	} else if err := feed.add(ch.Name, fetchedChannel); err != nil {
+		fmt.Fprintf(logTo, "[WARNING]: failed to refresh channel '%v': %v\n", ch.Name, err)
+		sched.Failures++
+	} else {
+		sched.Failures = 0
+	}
+	sched.LastRun = now
+	sched.NextRun = now.Add(nextInterval(ch, sched.Failures))
+	if err := feed.setSchedule(ch.Name, sched); err != nil {
+		fmt.Fprintf(logTo, "[WARNING]: failed to persist schedule for '%v': %v\n", ch.Name, err)
+	}
+}
+
+// runScheduler walks every subscribed channel once a minute and dispatches any channel whose
+// NextRun has passed to a bounded worker pool, so channels that upload often get refreshed sooner
+// than ones that rarely do, instead of every channel sharing a single global refresh rate.
+func runScheduler(concurrency int) {
+	jobs := make(chan *channel, 64)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			for ch := range jobs {
+				refreshChannel(ch)
+			}
+		}()
+	}
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for range ticker.C {
+		subs, err := feed.subs()
+		if err != nil {
+			fmt.Fprintf(logTo, "[ERROR]: scheduler failed to retrieve subs: %v\n", err)
+			continue
+		}
+		now := time.Now()
+		for _, ch := range subs {
+			if ch.Schedule != nil && ch.Schedule.NextRun.After(now) {
+				continue
+			}
+			select {
+			case jobs <- ch:
+			default:
+				fmt.Fprintf(logTo, "[WARNING]: scheduler queue full, skipping channel '%v' this tick\n", ch.Name)
+			}
+		}
+	}
+}
+
+func handleStatus(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			fmt.Fprintf(logTo, "[ERROR]: 'status' handler failed to accept connection\n")
+			return
+		}
+		go func(c net.Conn) {
+			defer c.Close()
+			chs, err := feed.subs()
+			if err != nil {
+				fmt.Fprintf(logTo, "[ERROR]: failed to retrieve subs: %v\n", err)
+				writeFrame(c, failure, []byte(err.Error()))
+				return
+			}
+			var lines []string
+			for _, ch := range chs {
+				s := ch.Schedule
+				if s == nil {
+					lines = append(lines, fmt.Sprintf("%v: not yet scheduled", ch.Name))
+					continue
+				}
+				lines = append(lines, fmt.Sprintf("%v: lastRun=%v nextRun=%v failures=%v",
+					ch.Name, s.LastRun.Format(time.RFC3339), s.NextRun.Format(time.RFC3339), s.Failures))
+			}
+			response := strings.Join(lines, "\n")
+			if len(response) == 0 {
+				response = "no subscriptions"
+			}
+			writeFrame(c, success, []byte(response))
+		}(conn)
+	}
+}
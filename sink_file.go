@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// FileSink writes downloaded videos to files named <videoID>.mp4 under a local directory.
+type FileSink struct {
+	dir string
+}
+
+func newFileSink(dir string) (*FileSink, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create download dir '%v': %v", dir, err)
+	}
+	return &FileSink{dir: dir}, nil
+}
+
+func (fs *FileSink) Put(ctx context.Context, videoID string, r io.Reader, size int64) error {
+	path := filepath.Join(fs.dir, videoID+".mp4")
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create '%v': %v", path, err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, r); err != nil {
+		return fmt.Errorf("failed to write '%v': %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// s3PartSize is the chunk size uploaded per UploadPart call; S3 requires every part but the last
+// to be at least 5MiB.
+const s3PartSize = 8 * 1024 * 1024
+
+// S3Sink uploads downloaded videos to an S3 bucket as <videoID>.mp4, driving the low-level
+// CreateMultipartUpload/UploadPart/CompleteMultipartUpload calls directly so large videos don't
+// need to be buffered in memory, aborting the upload on any failure.
+type S3Sink struct {
+	bucket string
+	client *s3.Client
+}
+
+func newS3Sink(bucket string) (*S3Sink, error) {
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %v", err)
+	}
+	return &S3Sink{bucket: bucket, client: s3.NewFromConfig(cfg)}, nil
+}
+
+func (ss *S3Sink) Put(ctx context.Context, videoID string, r io.Reader, size int64) error {
+	key := videoID + ".mp4"
+	created, err := ss.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(ss.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create multipart upload for '%v': %v", key, err)
+	}
+	uploadId := created.UploadId
+
+	var parts []types.CompletedPart
+	buf := make([]byte, s3PartSize)
+	for partNumber := int32(1); ; partNumber++ {
+		n, readErr := io.ReadFull(r, buf)
+		if n > 0 {
+			uploaded, err := ss.client.UploadPart(ctx, &s3.UploadPartInput{
+				Bucket:     aws.String(ss.bucket),
+				Key:        aws.String(key),
+				UploadId:   uploadId,
+				PartNumber: aws.Int32(partNumber),
+				Body:       bytes.NewReader(buf[:n]),
+			})
+			if err != nil {
+				ss.abort(ctx, key, uploadId)
+				return fmt.Errorf("failed to upload part %v of '%v': %v", partNumber, key, err)
+			}
+			parts = append(parts, types.CompletedPart{ETag: uploaded.ETag, PartNumber: aws.Int32(partNumber)})
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			ss.abort(ctx, key, uploadId)
+			return fmt.Errorf("failed to read '%v' for upload: %v", key, readErr)
+		}
+	}
+
+	if _, err := ss.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(ss.bucket),
+		Key:             aws.String(key),
+		UploadId:        uploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	}); err != nil {
+		ss.abort(ctx, key, uploadId)
+		return fmt.Errorf("failed to complete multipart upload for '%v': %v", key, err)
+	}
+	return nil
+}
+
+func (ss *S3Sink) abort(ctx context.Context, key string, uploadId *string) {
+	if _, err := ss.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(ss.bucket),
+		Key:      aws.String(key),
+		UploadId: uploadId,
+	}); err != nil {
+		fmt.Fprintf(logTo, "[WARNING]: failed to abort multipart upload for '%v': %v\n", key, err)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// event is a single new-video notification pushed to watch subscribers.
+type event struct {
+	Channel   string `json:"channel"`
+	Title     string `json:"title"`
+	VideoId   string `json:"videoId"`
+	Published string `json:"published"`
+}
+
+type watchSub struct {
+	ch     chan *event
+	filter map[string]struct{} // nil/empty means "all channels"
+}
+
+// watchBroker fans new-video events out to every registered subscriber, each optionally filtered
+// down to a subset of channel names, so multiple `watch` clients can follow different slices of
+// the feed without polling.
+type watchBroker struct {
+	mu   sync.RWMutex
+	subs sync.Map // id -> *watchSub
+}
+
+var broker = &watchBroker{}
+
+func (wb *watchBroker) register(filter map[string]struct{}) (id string, ch chan *event) {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	s := &watchSub{ch: make(chan *event, 16), filter: filter}
+	id = fmt.Sprintf("%p", s)
+	wb.subs.Store(id, s)
+	return id, s.ch
+}
+
+func (wb *watchBroker) unregister(id string) {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	if v, ok := wb.subs.LoadAndDelete(id); ok {
+		close(v.(*watchSub).ch)
+	}
+}
+
+func (wb *watchBroker) publish(ev *event) {
+	wb.mu.RLock()
+	defer wb.mu.RUnlock()
+	wb.subs.Range(func(_, v any) bool {
+		s := v.(*watchSub)
+		if len(s.filter) > 0 {
+			if _, ok := s.filter[ev.Channel]; !ok {
+				return true
+			}
+		}
+		select {
+		case s.ch <- ev:
+		default:
+			fmt.Fprintf(logTo, "[WARNING]: watch subscriber channel full, dropping event for '%v'\n", ev.Channel)
+		}
+		return true
+	})
+}
+
+func parseWatchFilter(raw []byte) map[string]struct{} {
+	s := strings.TrimSpace(string(raw))
+	if s == "" || s == "*" {
+		return nil
+	}
+	filter := make(map[string]struct{})
+	for _, name := range strings.Split(s, ",") {
+		filter[normalizeName(name)] = struct{}{}
+	}
+	return filter
+}
+
+// writeWatchFrame sends a single new-video event as a frame, JSON-encoded in the payload.
+func writeWatchFrame(c net.Conn, ev *event) error {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %v", err)
+	}
+	return writeFrame(c, success, payload)
+}
+
+func handleWatch(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			fmt.Fprintf(logTo, "[ERROR]: 'watch' handler failed to accept connection\n")
+			return
+		}
+		go func(c net.Conn) {
+			defer c.Close()
+			// NOTE: the subscriber is registered only once the first frame arrives (or the client closes
+			// its write side without sending one); an empty payload or "*" means "all channels"
+			_, filterBuf, _ := readFrame(c)
+			id, ch := broker.register(parseWatchFilter(filterBuf))
+			defer broker.unregister(id)
+			fmt.Fprintf(logTo, "[INFO]: watch subscriber '%v' registered\n", id)
+			for ev := range ch {
+				if err := writeWatchFrame(c, ev); err != nil {
+					fmt.Fprintf(logTo, "[INFO]: watch subscriber '%v' disconnected: %v\n", id, err)
+					return
+				}
+			}
+		}(conn)
+	}
+}
@@ -23,6 +23,37 @@ type db interface {
 	get(string) (*channel, error)
 	rm(string) error
 	subs() ([]*channel, error)
+	setFilters(string, *filters) error
+	setDownloadStatus(string, string, downloadStatus, string) error
+	setSchedule(string, *schedule) error
+	setAutoDownload(string, bool) error
+}
+
+// channelLocks serializes add() per channel for db implementations whose backing store has no
+// built-in read-modify-write isolation (sqliteDb, redisDb), so a scheduler dispatch and a
+// concurrent refresh of the same channel can't both diff against the same stored cursor and fire
+// duplicate notify()/watch/auto-download events. localDb needs no equivalent since its single
+// sync.RWMutex already makes add() atomic.
+type channelLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func newChannelLocks() *channelLocks {
+	return &channelLocks{locks: make(map[string]*sync.Mutex)}
+}
+
+// lock acquires the per-channel lock for c, returning a func to release it.
+func (cl *channelLocks) lock(c string) func() {
+	cl.mu.Lock()
+	l, ok := cl.locks[c]
+	if !ok {
+		l = &sync.Mutex{}
+		cl.locks[c] = l
+	}
+	cl.mu.Unlock()
+	l.Lock()
+	return l.Unlock
 }
 
 type localDb struct {
@@ -36,12 +67,24 @@ type video struct {
 	Title       string `xml:"title"`
 	VideoId     string `xml:"videoId"`
 	Description string `xml:"group>description"`
+	Published   string `xml:"published"`
+	Link        struct {
+		Href string `xml:"href,attr"`
+	} `xml:"link"`
+	Duration struct {
+		Seconds int `xml:"seconds,attr"`
+	} `xml:"group>duration"`
+	ContentType string `xml:"group>content>type,attr"`
 }
 type videos []*video
 type channel struct {
-	Name   string
-	URL    string
-	Videos []*video `xml:"entry"`
+	Name         string
+	URL          string
+	Videos       []*video `xml:"entry"`
+	Filters      *filters
+	AutoDownload bool
+	Downloads    map[string]*downloadRecord
+	Schedule     *schedule
 }
 
 type endpoint struct {
@@ -58,7 +101,7 @@ const (
 )
 
 const (
-	listenersSize             = 8
+	listenersSize             = 12
 	maxFeedSize               = 7
 	channelURLBase     string = "https://www.youtube.com/@"
 	channelURLByIDBase string = "https://www.youtube.com/channel/"
@@ -71,12 +114,31 @@ var logTo *os.File
 
 var (
 	listeners listenersChan = listenersChan(make(chan net.Listener, listenersSize))
-	feed      *localDb      = &localDb{c: make(map[string]*channel)}
+	feed      db
 
 	flagNotify      *bool
 	flagRefreshRate *int
 )
 
+func newLocalDb() *localDb {
+	return &localDb{c: make(map[string]*channel)}
+}
+
+// newStore builds the db implementation selected via -store: memory (default, in-process only),
+// sqlite (path is a file on disk) or redis (addr is a host:port).
+func newStore(kind string, path string, addr string) (db, error) {
+	switch kind {
+	case "", "memory":
+		return newLocalDb(), nil
+	case "sqlite":
+		return newSQLiteDb(path)
+	case "redis":
+		return newRedisDb(addr)
+	default:
+		return nil, fmt.Errorf("unknown store %q, expected memory, sqlite or redis", kind)
+	}
+}
+
 func (lc listenersChan) add(l net.Listener) {
 	if len(lc)+1 > listenersSize {
 		fmt.Fprintf(os.Stderr, "[ERROR]: too many listeners opened\n")
@@ -118,6 +180,17 @@ func (c *channel) String() string {
 	return fmt.Sprintf("%v\n\t%v\n\n%v", c.Name, c.URL, videos(c.Videos).String())
 }
 
+// filteredString renders c the same way String() does, but drops videos excluded by c.Filters.
+func (c *channel) filteredString() string {
+	var vs videos
+	for _, v := range c.Videos {
+		if c.Filters.matches(v) {
+			vs = append(vs, v)
+		}
+	}
+	return fmt.Sprintf("%v\n\t%v\n\n%v", c.Name, c.URL, vs.String())
+}
+
 func (ep endpoint) serve() {
 	if err := os.RemoveAll(ep.sockName); err != nil {
 		fmt.Fprintf(os.Stderr, "[ERROR]: failed to remove all from socket '%v': %v\n", ep.sockName, err)
@@ -213,34 +286,133 @@ func normalizeName(c string) string {
 	return c
 }
 
+// diffAndNotify compares freshly fetched videos against the previously stored ones for channel c,
+// firing notify() and publishing a watch event for every video newer than the stored cursor that
+// passes f (oldest-of-the-new first, matching upload order), and returns the video slice that
+// should be persisted as the channel's new state (capped at maxFeedSize) alongside the VideoIds of
+// any new videos that should be auto-downloaded (when autoDownload is set). Queuing those
+// downloads is left to the caller, since db implementations may be holding a lock that
+// queueDownload's write path (feed.setDownloadStatus) would re-acquire. Shared by every db
+// implementation so the "what's new" cursor behaves the same regardless of where a channel's state
+// is persisted.
+func diffAndNotify(c string, stored []*video, fetched []*video, f *filters, autoDownload bool) ([]*video, []string) {
+	if len(stored) == 0 {
+		return fetched[:min(maxFeedSize, len(fetched))], nil
+	}
+	latest := stored[0]
+	i := 0
+	var toDownload []string
+	for j, vid := range fetched {
+		if vid.VideoId == latest.VideoId {
+			i = j
+			if i > 0 {
+				for k := i - 1; k >= 0; k-- {
+					if !f.matches(fetched[k]) {
+						continue
+					}
+					broker.publish(&event{Channel: c, Title: fetched[k].Title, VideoId: fetched[k].VideoId, Published: fetched[k].Published})
+					if autoDownload {
+						toDownload = append(toDownload, fetched[k].VideoId)
+					}
+				}
+			}
+			if *flagNotify && i > 0 {
+				var err error
+				for k := i - 1; k >= 0 && err == nil; k-- {
+					if !f.matches(fetched[k]) {
+						continue
+					}
+					err = notify(c, fetched[k])
+				}
+			}
+			break
+		}
+	}
+	merged := append(fetched[:i], stored...)
+	return merged[:min(maxFeedSize, len(merged))], toDownload
+}
+
+// queueDownloads queues every videoId in toDownload for channel c, logging (rather than failing
+// add()) on error so a download-queue hiccup never drops a subscription update.
+func queueDownloads(c string, toDownload []string) {
+	for _, videoId := range toDownload {
+		if err := queueDownload(c, videoId); err != nil {
+			fmt.Fprintf(logTo, "[WARNING]: failed to auto-queue download of '%v': %v\n", videoId, err)
+		}
+	}
+}
+
 func (ldb *localDb) add(c string, ch *channel) error {
 	if len(ch.Videos) == 0 {
 		return fmt.Errorf("channel with no videos")
 	}
 	c = normalizeName(c)
 	ldb.Lock()
-	defer ldb.Unlock()
 	ldbCh, ok := ldb.c[c]
 	if ok && len(ldbCh.Videos) > 0 {
-		latest := ldbCh.Videos[0]
-		i := 0
-		for j, vid := range ch.Videos {
-			if vid.VideoId == latest.VideoId {
-				i = j
-				if *flagNotify && i > 0 {
-					var err error
-					for k := 0; k < i && err == nil; k++ {
-						err = notify(c, ch.Videos[k])
-					}
-				}
-				break
-			}
-		}
-		ldbCh.Videos = append(ch.Videos[:i], ldbCh.Videos[:min(maxFeedSize, len(ldbCh.Videos))]...)
+		ch.Filters = ldbCh.Filters
+		ch.AutoDownload = ldbCh.AutoDownload
+		ch.Downloads = ldbCh.Downloads
+		var toDownload []string
+		ldbCh.Videos, toDownload = diffAndNotify(c, ldbCh.Videos, ch.Videos, ch.Filters, ch.AutoDownload)
+		ldb.Unlock()
+		queueDownloads(c, toDownload)
 		return nil
 	}
 	ch.Videos = ch.Videos[:min(maxFeedSize, len(ch.Videos))]
 	ldb.c[c] = ch
+	ldb.Unlock()
+	return nil
+}
+
+func (ldb *localDb) setDownloadStatus(c string, videoId string, st downloadStatus, reason string) error {
+	c = normalizeName(c)
+	ldb.Lock()
+	defer ldb.Unlock()
+	ch, ok := ldb.c[c]
+	if !ok {
+		return fmt.Errorf("not subscribed to channel '%v'", c)
+	}
+	if ch.Downloads == nil {
+		ch.Downloads = make(map[string]*downloadRecord)
+	}
+	ch.Downloads[videoId] = &downloadRecord{VideoId: videoId, Status: st, Reason: reason}
+	return nil
+}
+
+func (ldb *localDb) setFilters(c string, f *filters) error {
+	c = normalizeName(c)
+	ldb.Lock()
+	defer ldb.Unlock()
+	ch, ok := ldb.c[c]
+	if !ok {
+		return fmt.Errorf("not subscribed to channel '%v'", c)
+	}
+	ch.Filters = f
+	return nil
+}
+
+func (ldb *localDb) setAutoDownload(c string, enabled bool) error {
+	c = normalizeName(c)
+	ldb.Lock()
+	defer ldb.Unlock()
+	ch, ok := ldb.c[c]
+	if !ok {
+		return fmt.Errorf("not subscribed to channel '%v'", c)
+	}
+	ch.AutoDownload = enabled
+	return nil
+}
+
+func (ldb *localDb) setSchedule(c string, s *schedule) error {
+	c = normalizeName(c)
+	ldb.Lock()
+	defer ldb.Unlock()
+	ch, ok := ldb.c[c]
+	if !ok {
+		return fmt.Errorf("not subscribed to channel '%v'", c)
+	}
+	ch.Schedule = s
 	return nil
 }
 
@@ -331,30 +503,6 @@ func fetch(chName []byte, chURL []byte) (*channel, error) {
 	return &fetchedChannel, nil
 }
 
-// send is a function that takes the state (success/failure) and response message. It encodes the response in the following way
-// * first byte is the state, i.e. whether the request was successful or not.
-// * second and third byte form an exponent for base 2, where 2^exponent will fit the response message
-// * rest of the bytes is the response
-func send(c net.Conn, st state, resp string) {
-	pow := 1
-	for res := 2; res < len(resp); res *= 2 {
-		pow++
-	}
-	var msg []byte
-	msg = append(msg, byte(st))
-	msg = append(msg, byte(pow/10))
-	msg = append(msg, byte(pow%10))
-	msg = append(msg, []byte(resp)...)
-	c.Write(msg)
-
-	lines := bytes.Split(msg[3:], []byte("\n"))
-	if len(lines) == 0 {
-		fmt.Fprintf(logTo, "[WARNING]: sending empty response\n")
-	} else {
-		fmt.Fprintf(logTo, "[INFO]: sending %v bytes, '%v...'\n", len(resp), string(lines[0]))
-	}
-}
-
 func handleFetch(l net.Listener) {
 	for {
 		conn, err := l.Accept()
@@ -364,28 +512,26 @@ func handleFetch(l net.Listener) {
 		}
 		go func(c net.Conn) {
 			defer c.Close()
-			channelName := make([]byte, 128)
-			n, err := c.Read(channelName)
+			_, channelName, err := readFrame(c)
 			if err != nil {
-				fmt.Fprintf(logTo, "[ERROR]: didn't understand '%v' for 'fetch': %v\n", string(channelName), err)
-				send(c, failure, err.Error())
+				fmt.Fprintf(logTo, "[ERROR]: didn't understand request for 'fetch': %v\n", err)
+				writeFrame(c, failure, []byte(err.Error()))
 				return
 			}
-			channelName = channelName[:n]
 			channelURL, err := getChannelURL(channelName)
 			if err != nil {
 				fmt.Fprintf(logTo, "[ERROR]: failed to get channel '%v' url: %v\n", string(channelName), err)
-				send(c, failure, err.Error())
+				writeFrame(c, failure, []byte(err.Error()))
 				return
 			}
 
 			channelFetched, err := fetch(channelName, channelURL)
 			if err != nil {
 				fmt.Fprintf(logTo, "[ERROR]: failed to fetch '%v': %v\n", string(channelName), err)
-				send(c, failure, err.Error())
+				writeFrame(c, failure, []byte(err.Error()))
 				return
 			}
-			send(c, success, channelFetched.String())
+			writeFrame(c, success, []byte(channelFetched.String()))
 		}(conn)
 	}
 }
@@ -399,38 +545,36 @@ func handleAdd(l net.Listener) {
 		}
 		go func(c net.Conn) {
 			defer c.Close()
-			channelName := make([]byte, 128)
-			n, err := c.Read(channelName)
+			_, channelName, err := readFrame(c)
 			if err != nil {
-				fmt.Fprintf(logTo, "[ERROR]: didn't understand '%v' for 'add': %v\n", string(channelName), err)
-				send(c, failure, err.Error())
+				fmt.Fprintf(logTo, "[ERROR]: didn't understand request for 'add': %v\n", err)
+				writeFrame(c, failure, []byte(err.Error()))
 				return
 			}
-			channelName = channelName[:n]
 			_, err = feed.get(string(channelName))
 			if err == nil { // NOTE: already subbed
-				send(c, failure, fmt.Sprintf("already subscribed to channel %q", string(channelName)))
+				writeFrame(c, failure, []byte(fmt.Sprintf("already subscribed to channel %q", string(channelName))))
 				return
 			}
 			channelURL, err := getChannelURL(channelName)
 			if err != nil {
 				fmt.Fprintf(logTo, "[ERROR]: failed to get channel '%v' url: %v\n", string(channelName), err)
-				send(c, failure, err.Error())
+				writeFrame(c, failure, []byte(err.Error()))
 				return
 			}
 			channelFetched, err := fetch(channelName, channelURL)
 			if err != nil {
 				fmt.Fprintf(logTo, "[ERROR]: failed to fetch '%v': %v\n", string(channelName), err)
-				send(c, failure, err.Error())
+				writeFrame(c, failure, []byte(err.Error()))
 				return
 			}
 			err = feed.add(string(channelName), channelFetched)
 			if err != nil {
 				fmt.Fprintf(logTo, "[ERROR]: failed to add: %v\n", err)
-				send(c, failure, err.Error())
+				writeFrame(c, failure, []byte(err.Error()))
 				return
 			}
-			send(c, success, fmt.Sprintf("subscribed to channel %q", string(channelName)))
+			writeFrame(c, success, []byte(fmt.Sprintf("subscribed to channel %q", string(channelName))))
 		}(conn)
 	}
 }
@@ -444,21 +588,19 @@ func handleGet(l net.Listener) {
 		}
 		go func(c net.Conn) {
 			defer c.Close()
-			channelName := make([]byte, 128)
-			n, err := conn.Read(channelName)
+			_, channelName, err := readFrame(c)
 			if err != nil {
-				fmt.Fprintf(logTo, "[ERROR]: didn't understand '%v' for 'get': %v\n", string(channelName), err)
-				send(c, failure, err.Error())
+				fmt.Fprintf(logTo, "[ERROR]: didn't understand request for 'get': %v\n", err)
+				writeFrame(c, failure, []byte(err.Error()))
 				return
 			}
-			channelName = channelName[:n]
 			ch, err := feed.get(string(channelName))
 			if err != nil {
 				fmt.Fprintf(logTo, "[ERROR]: failed to get feed for channel '%v': %v\n", string(channelName), err)
-				send(c, failure, err.Error())
+				writeFrame(c, failure, []byte(err.Error()))
 				return
 			}
-			send(c, success, ch.String())
+			writeFrame(c, success, []byte(ch.filteredString()))
 			return
 		}(conn)
 	}
@@ -473,26 +615,26 @@ func handleRm(l net.Listener) {
 		}
 		go func(c net.Conn) {
 			defer c.Close()
-			channelName := make([]byte, 128)
-			n, err := c.Read(channelName)
+			_, channelName, err := readFrame(c)
 			if err != nil {
-				fmt.Fprintf(logTo, "[ERROR]: didn't understand '%v' for 'rm': %v\n", string(channelName), err)
-				send(c, failure, err.Error())
+				fmt.Fprintf(logTo, "[ERROR]: didn't understand request for 'rm': %v\n", err)
+				writeFrame(c, failure, []byte(err.Error()))
 				return
 			}
-			channelName = channelName[:n]
 			err = feed.rm(string(channelName))
 			if err != nil {
 				fmt.Fprintf(logTo, "[ERROR]: failed to rm channel '%v' from subscriptions: %v\n", string(channelName), err)
-				send(c, failure, err.Error())
+				writeFrame(c, failure, []byte(err.Error()))
 				return
 			}
-			send(c, success, fmt.Sprintf("unsubscribed from channel %q", string(channelName)))
+			writeFrame(c, success, []byte(fmt.Sprintf("unsubscribed from channel %q", string(channelName))))
 			return
 		}(conn)
 	}
 }
 
+// refresh immediately re-fetches every subscribed channel, bypassing the scheduler. Used by
+// handleRefresh for on-demand pokes via the socket.
 func refresh() {
 	subs, err := feed.subs()
 	if err != nil {
@@ -502,29 +644,15 @@ func refresh() {
 	var wg sync.WaitGroup
 	for _, sub := range subs {
 		wg.Add(1)
-		go func(channelName []byte, channelURL []byte) {
+		go func(ch *channel) {
 			defer wg.Done()
-			fetchedChannel, err := fetch(channelName, channelURL)
-			if err != nil {
-				fmt.Fprintf(logTo, "[ERROR]: failed to fetch '%v' for refresh: %v\n", string(channelName), err)
-				return
-			}
-			err = feed.add(string(channelName), fetchedChannel)
-			if err != nil {
-				fmt.Fprintf(logTo, "[WARNING]: failed to refresh channel '%v': %v\n", string(channelName), err)
-				return
-			}
-			return
-		}([]byte(sub.Name), []byte(sub.URL))
+			refreshChannel(ch)
+		}(sub)
 	}
 	wg.Wait()
 }
 
 func handleRefresh(l net.Listener) {
-	go func() {
-		<-time.After(time.Duration(*flagRefreshRate) * time.Minute)
-		refresh()
-	}()
 	for {
 		conn, err := l.Accept()
 		if err != nil {
@@ -579,21 +707,19 @@ func handleSearch(l net.Listener) {
 		}
 		go func(c net.Conn) {
 			defer c.Close()
-			query := make([]byte, 128)
-			n, err := c.Read(query)
+			_, query, err := readFrame(c)
 			if err != nil {
 				fmt.Fprintf(logTo, "[ERROR]: 'search' failed to read input: %v\n", err)
-				send(c, failure, err.Error())
+				writeFrame(c, failure, []byte(err.Error()))
 				return
 			}
-			query = query[:n]
 			chNames, err := search(query)
 			if err != nil {
 				fmt.Fprintf(logTo, "[ERROR]: %v\n", err)
-				send(c, failure, err.Error())
+				writeFrame(c, failure, []byte(err.Error()))
 				return
 			}
-			send(c, success, chNames)
+			writeFrame(c, success, []byte(chNames))
 		}(conn)
 	}
 }
@@ -607,16 +733,14 @@ func handleHealth(l net.Listener) {
 		}
 		go func(c net.Conn) {
 			defer c.Close()
-			buf := make([]byte, 128)
-			n, err := c.Read(buf)
+			_, buf, err := readFrame(c)
 			if err != nil {
 				fmt.Fprintf(logTo, "[WARN]: failed to read input on 'health': %v\n", err)
-				send(c, failure, err.Error())
+				writeFrame(c, failure, []byte(err.Error()))
 				return
 			}
-			buf = buf[:n]
 			response := fmt.Sprintf("%q", string(buf))
-			send(c, success, response)
+			writeFrame(c, success, []byte(response))
 		}(conn)
 	}
 }
@@ -633,7 +757,7 @@ func handleSubs(l net.Listener) {
 			chs, err := feed.subs()
 			if err != nil {
 				fmt.Fprintf(logTo, "[ERROR]: failed to retrieve subs: %v\n", err)
-				send(c, failure, err.Error())
+				writeFrame(c, failure, []byte(err.Error()))
 				return
 			}
 			var channelNames []string
@@ -644,24 +768,38 @@ func handleSubs(l net.Listener) {
 			if len(response) == 0 {
 				response = "no subscriptions"
 			}
-			send(c, success, response)
+			writeFrame(c, success, []byte(response))
 		}(conn)
 	}
 }
 
 func help() {
-	fmt.Printf("%v [-notify={true|false}] [-subs=/path/to/subs/file] [-refrate={minutes}] [-debug]\n", os.Args[0])
+	fmt.Printf("%v [-notify={true|false}] [-subs=/path/to/subs/file] [-refrate={minutes}] [-store={memory|sqlite|redis}] [-debug]\n", os.Args[0])
 	flag.PrintDefaults()
 	fmt.Printf("\nExamples:\n")
 	fmt.Printf("\t* %v -help\n", os.Args[0])
 	fmt.Printf("\t* %v -subs=./example.subs -refrate=7 -notify\n", os.Args[0])
 	fmt.Printf("\t* %v -subs=./example.subs -refrate=7 -notify=false -debug\n", os.Args[0])
+	fmt.Printf("\t* %v -store=sqlite -storepath=/var/lib/ytfd/ytfd.db\n", os.Args[0])
+	fmt.Printf("\t* %v -store=redis -storeaddr=localhost:6379\n", os.Args[0])
+	fmt.Printf("\t* %v -downloaddir=/var/lib/ytfd/videos -dlconcurrency=4\n", os.Args[0])
+	fmt.Printf("\t* %v -s3bucket=my-ytfd-videos\n", os.Args[0])
+	fmt.Printf("\t* %v -minrefrate=2 -refrate=30 -refconcurrency=8\n", os.Args[0])
+	fmt.Printf("\t* printf '\\x01\\x01\\x00\\x00\\x00\\x15somechannel\\x00auto=true' | nc -U /tmp/ytfd.download.sock  # enable auto-download for a channel (version, state, 4-byte length, payload)\n")
 }
 
 func main() {
 	flagNotify = flag.Bool("notify", true, "creates dunstify notification when a new video for a subscribed channel is detected. Depends on dunstify. If dunstify is not detected in the system, internal flag value is set to false")
 	flagSubsFromFile := flag.String("subs", "", "path to file that contains names of subscribed channels, one per each line")
-	flagRefreshRate = flag.Int("refrate", 15, "refresh rate in minutes, i.e. how often daemon checks youtube")
+	flagRefreshRate = flag.Int("refrate", 15, "maximum refresh rate in minutes, i.e. how rarely the daemon checks a channel that uploads infrequently")
+	flagMinRefreshRate := flag.Int("minrefrate", 5, "minimum refresh rate in minutes, i.e. how often the daemon checks a channel that uploads frequently")
+	flagRefreshConcurrency := flag.Int("refconcurrency", 4, "number of channels refreshed concurrently by the scheduler")
+	flagStore := flag.String("store", "memory", "persistent storage backend for subscriptions: memory, sqlite or redis")
+	flagStorePath := flag.String("storepath", "/tmp/ytfd.db", "path to the sqlite database file (used when -store=sqlite)")
+	flagStoreAddr := flag.String("storeaddr", "localhost:6379", "redis server address (used when -store=redis)")
+	flagDownloadDir = flag.String("downloaddir", "/tmp/ytfd-downloads", "directory downloaded videos are written to when -s3bucket is unset")
+	flagDownloadConcurrency = flag.Int("dlconcurrency", 2, "number of concurrent video downloads")
+	flagS3Bucket := flag.String("s3bucket", "", "S3 bucket downloaded videos are uploaded to; if unset, downloads are written under -downloaddir instead")
 	flagHelp := flag.Bool("help", false, "print help")
 	flagDebug := flag.Bool("debug", false, "sets logging to stderr")
 	flag.Parse()
@@ -693,6 +831,24 @@ func main() {
 		os.Exit(0)
 	}()
 
+	var err error
+	feed, err = newStore(*flagStore, *flagStorePath, *flagStoreAddr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR]: failed to initialize '%v' store: %v\n", *flagStore, err)
+		return
+	}
+
+	downloadSink, err = newSink(*flagS3Bucket, *flagDownloadDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR]: failed to initialize download sink: %v\n", err)
+		return
+	}
+	startDownloadWorkers(*flagDownloadConcurrency)
+
+	minRate = time.Duration(*flagMinRefreshRate) * time.Minute
+	maxRate = time.Duration(*flagRefreshRate) * time.Minute
+	go runScheduler(*flagRefreshConcurrency)
+
 	// feat: subs from file
 	go subsFromFile(*flagSubsFromFile)
 
@@ -725,6 +881,22 @@ func main() {
 			sockName: "/tmp/ytfd.subs.sock",
 			handle:   handleSubs,
 		},
+		{
+			sockName: "/tmp/ytfd.watch.sock",
+			handle:   handleWatch,
+		},
+		{
+			sockName: "/tmp/ytfd.filter.sock",
+			handle:   handleFilter,
+		},
+		{
+			sockName: "/tmp/ytfd.download.sock",
+			handle:   handleDownload,
+		},
+		{
+			sockName: "/tmp/ytfd.status.sock",
+			handle:   handleStatus,
+		},
 		{
 			sockName: "/tmp/ytfd.health.sock",
 			handle:   handleHealth,